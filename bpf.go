@@ -0,0 +1,484 @@
+//go:build seccomp && (!cgo || nativebpf)
+// +build seccomp
+// +build !cgo nativebpf
+
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// ValidateProfile does a basic validation for the provided seccomp profile
+// string, compiling it with the cgo-free BPF backend.
+func ValidateProfile(content string) error {
+	profile := &Seccomp{}
+	if err := json.Unmarshal([]byte(content), &profile); err != nil {
+		return errors.Wrap(err, "decoding seccomp profile")
+	}
+
+	if _, err := Compile(profile); err != nil {
+		return errors.Wrap(err, "build seccomp filter")
+	}
+
+	return nil
+}
+
+// Offsets into the kernel's `struct seccomp_data`:
+//
+//	struct seccomp_data {
+//		int   nr;
+//		__u32 arch;
+//		__u64 instruction_pointer;
+//		__u64 args[6];
+//	};
+const (
+	offsetNR   = 0
+	offsetArch = 4
+)
+
+func argOffsetLo(index uint) uint32 { return uint32(16 + 8*index) }
+func argOffsetHi(index uint) uint32 { return argOffsetLo(index) + 4 }
+
+// nativeArch maps runtime.GOARCH to the AUDIT_ARCH_* value the kernel
+// places in seccomp_data.arch, and to the profile Arch name it corresponds
+// to.
+func nativeArch() (audit uint32, arch Arch, err error) {
+	switch runtime.GOARCH {
+	case "amd64":
+		return unix.AUDIT_ARCH_X86_64, ArchX86_64, nil
+	case "386":
+		return unix.AUDIT_ARCH_I386, ArchX86, nil
+	case "arm64":
+		return unix.AUDIT_ARCH_AARCH64, ArchAARCH64, nil
+	case "arm":
+		return unix.AUDIT_ARCH_ARM, ArchARM, nil
+	case "s390x":
+		return unix.AUDIT_ARCH_S390X, ArchS390X, nil
+	case "ppc64le":
+		return unix.AUDIT_ARCH_PPC64LE, ArchPPC64LE, nil
+	default:
+		return 0, "", errors.Errorf("unsupported GOARCH %q for native seccomp compiler", runtime.GOARCH)
+	}
+}
+
+// bpfInstr is a BPF instruction whose jump targets are still symbolic
+// labels rather than resolved relative offsets or a JA immediate. asm
+// resolves a slice of these into a *unix.SockFprog.
+type bpfInstr struct {
+	code uint16
+	k    uint32
+
+	// jt/jf name the labels this instruction jumps to on true/false.
+	// Left empty for plain (non-jump) instructions. A BPF_JA only uses jt.
+	jt, jf string
+}
+
+// asm is the symbolic assembler used by Compile: instructions are
+// appended with stmt/jumpEq/jumpOp, targets are marked with label, and resolve()
+// turns labels into concrete offsets, splicing in BPF_JA trampolines
+// where a conditional jump would otherwise need to travel further than
+// the 8-bit jt/jf fields allow.
+type asm struct {
+	instrs []bpfInstr
+	labels map[string]int
+	next   int
+}
+
+func newAsm() *asm {
+	return &asm{labels: map[string]int{}}
+}
+
+// newLabel returns a fresh label name, for instructions whose target
+// isn't otherwise named.
+func (a *asm) newLabel() string {
+	a.next++
+	return fmt.Sprintf("$L%d", a.next)
+}
+
+func (a *asm) stmt(code uint16, k uint32) {
+	a.instrs = append(a.instrs, bpfInstr{code: code, k: k})
+}
+
+func (a *asm) jumpEq(k uint32, jt, jf string) {
+	a.instrs = append(a.instrs, bpfInstr{code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, k: k, jt: jt, jf: jf})
+}
+
+func (a *asm) jumpOp(op uint16, k uint32, jt, jf string) {
+	a.instrs = append(a.instrs, bpfInstr{code: unix.BPF_JMP | op | unix.BPF_K, k: k, jt: jt, jf: jf})
+}
+
+func (a *asm) label(name string) {
+	a.labels[name] = len(a.instrs)
+}
+
+// maxJump is the largest offset that fits in the 8-bit jt/jf fields of a
+// conditional jump instruction.
+const maxJump = 255
+
+// resolve turns the symbolic instruction stream into concrete BPF,
+// inserting a BPF_JA trampoline after any instruction whose conditional
+// jump target lands further away than maxJump instructions - BPF_JA
+// carries a 32-bit immediate and so can always bridge the remaining
+// distance.
+func (a *asm) resolve() ([]unix.SockFilter, error) {
+	instrs := append([]bpfInstr(nil), a.instrs...)
+	labels := make(map[string]int, len(a.labels))
+	for k, v := range a.labels {
+		labels[k] = v
+	}
+
+	trampoline := func(i int, target string) (string, error) {
+		pos, ok := labels[target]
+		if !ok {
+			return "", errors.Errorf("unresolved label %q", target)
+		}
+		name := fmt.Sprintf("$T%d", len(instrs))
+		for k, v := range labels {
+			if v > i {
+				labels[k] = v + 1
+			}
+		}
+		instrs = append(instrs[:i+1], append([]bpfInstr{{code: unix.BPF_JMP | unix.BPF_JA, jt: target}}, instrs[i+1:]...)...)
+		labels[name] = i + 1
+		_ = pos
+		return name, nil
+	}
+
+	// Walk backwards so a trampoline spliced in after instruction i only
+	// lengthens jumps of instructions that are still to be checked.
+	for i := len(instrs) - 1; i >= 0; i-- {
+		in := instrs[i]
+		if in.jt == "" && in.jf == "" {
+			continue
+		}
+
+		if in.jt != "" {
+			pos, ok := labels[in.jt]
+			if !ok {
+				return nil, errors.Errorf("unresolved label %q", in.jt)
+			}
+			if pos-(i+1) > maxJump {
+				t, err := trampoline(i, in.jt)
+				if err != nil {
+					return nil, err
+				}
+				instrs[i].jt = t
+			}
+		}
+		if in.jf != "" {
+			pos, ok := labels[in.jf]
+			if !ok {
+				return nil, errors.Errorf("unresolved label %q", in.jf)
+			}
+			if pos-(i+1) > maxJump {
+				t, err := trampoline(i, in.jf)
+				if err != nil {
+					return nil, err
+				}
+				instrs[i].jf = t
+			}
+		}
+	}
+
+	out := make([]unix.SockFilter, len(instrs))
+	for i, in := range instrs {
+		f := unix.SockFilter{Code: in.code, K: in.k}
+		switch {
+		case in.jt != "" && in.code&0xf0 == unix.BPF_JA:
+			f.K = uint32(labels[in.jt] - (i + 1))
+		case in.jt != "" || in.jf != "":
+			f.Jt = uint8(labels[in.jt] - (i + 1))
+			f.Jf = uint8(labels[in.jf] - (i + 1))
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// Compile compiles a Seccomp profile into a classic BPF program equivalent
+// to the filter libseccomp would build for it, for use without cgo or
+// libseccomp-golang.
+func Compile(profile *Seccomp) (*unix.SockFprog, error) {
+	if profile == nil {
+		return nil, errors.New("cannot compile nil profile")
+	}
+
+	defaultAction, err := rawAction(profile.DefaultAction, profile.DefaultErrnoRet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "convert default action %s", profile.DefaultAction)
+	}
+
+	audit, arch, err := nativeArch()
+	if err != nil {
+		return nil, err
+	}
+
+	archs := profile.Architectures
+	if len(archs) == 0 {
+		archs = []Arch{arch}
+	}
+	supported := false
+	for _, a := range archs {
+		if a == arch {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		// A profile that doesn't target this architecture at all can't
+		// produce a meaningful filter for it, so it is rejected.
+		return nil, errors.Errorf("profile does not target runtime architecture %s", arch)
+	}
+
+	a := newAsm()
+	end := "$end"
+	badArch := "$badarch"
+	tree := "$tree"
+
+	// Prologue: anything running under a foreign arch ABI falls straight
+	// to KILL_THREAD, matching libseccomp's default handling of calls
+	// made through an unexpected architecture (e.g. a 32-bit compat call
+	// against a 64-bit filter).
+	a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, offsetArch)
+	a.jumpEq(audit, tree, badArch)
+	a.label(badArch)
+	a.stmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_THREAD)
+	a.label(tree)
+	a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, offsetNR)
+
+	// Dedup identical (action, no-args) tails: every syscall that resolves
+	// to the same bare action shares one RET instruction. Classic BPF
+	// jumps are forward-only (jt/jf are unsigned offsets), so that shared
+	// RET must be placed after every comparison that targets it - i.e.
+	// after the *last* bare syscall using a given action, not the first.
+	// lastBareForAction records that last index up front so the main
+	// loop knows when it's safe to emit the tail.
+	actionTail := map[uint32]string{}
+	lastBareForAction := map[uint32]int{}
+	for i, call := range profile.Syscalls {
+		if call == nil || len(call.Args) != 0 {
+			continue
+		}
+		if _, ok := syscallNumber(call.Name); !ok {
+			continue
+		}
+		if act, err := rawAction(call.Action, call.ErrnoRet); err == nil {
+			lastBareForAction[act] = i
+		}
+	}
+
+	for i, call := range profile.Syscalls {
+		if call == nil {
+			return nil, errors.New("encountered nil syscall while compiling seccomp profile")
+		}
+		if len(call.Name) == 0 {
+			return nil, errors.New("empty string is not a valid syscall")
+		}
+
+		nr, ok := syscallNumber(call.Name)
+		if !ok {
+			// Not in syscallNumbers: skip it without reporting anything,
+			// as the cgo-based matchSyscall skips names that
+			// GetSyscallFromName can't resolve.
+			continue
+		}
+
+		callAct, err := rawAction(call.Action, call.ErrnoRet)
+		if err != nil {
+			return nil, errors.Wrapf(err, "convert action %s", call.Action)
+		}
+
+		next := a.newLabel()
+
+		if len(call.Args) == 0 {
+			tail := actionTail[callAct]
+			if tail == "" {
+				tail = a.newLabel()
+				actionTail[callAct] = tail
+			}
+			a.jumpEq(uint32(nr), tail, next)
+			if i == lastBareForAction[callAct] {
+				a.label(tail)
+				a.stmt(unix.BPF_RET|unix.BPF_K, callAct)
+			}
+		} else {
+			match := a.newLabel()
+			a.jumpEq(uint32(nr), match, next)
+			a.label(match)
+			pass := a.newLabel()
+			if err := emitArgMatch(a, call.Args, pass, next); err != nil {
+				return nil, errors.Wrapf(err, "emit argument match for syscall %s", call.Name)
+			}
+			a.label(pass)
+			a.stmt(unix.BPF_RET|unix.BPF_K, callAct)
+		}
+
+		a.label(next)
+	}
+
+	a.label(end)
+	a.stmt(unix.BPF_RET|unix.BPF_K, defaultAction)
+
+	filters, err := a.resolve()
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve BPF jump targets")
+	}
+
+	return &unix.SockFprog{
+		Len:    uint16(len(filters)),
+		Filter: &filters[0],
+	}, nil
+}
+
+// emitArgMatch emits the comparisons for a single Args rule. All *Arg
+// entries must match (they are AND-ed together) for control to reach
+// pass; any mismatch falls through to fail. Arguments are 64-bit, so
+// each one compares both the high and low 32-bit halves (loaded via
+// argOffsetHi/argOffsetLo) rather than just the low word.
+func emitArgMatch(a *asm, args []*Arg, pass, fail string) error {
+	for i, arg := range args {
+		if arg == nil {
+			return errors.New("cannot match nil argument")
+		}
+
+		ok := pass
+		if i < len(args)-1 {
+			ok = a.newLabel()
+		}
+
+		if err := emit64Compare(a, arg, ok, fail); err != nil {
+			return err
+		}
+
+		if ok != pass {
+			a.label(ok)
+		}
+	}
+	return nil
+}
+
+// emit64Compare emits the hi/lo comparison ladder for a single 64-bit
+// argument, matching arg.Value (and, for OpMaskedEqual, masked by
+// arg.ValueTwo) against the argument at arg.Index. Control reaches ok on
+// a match and fail otherwise.
+func emit64Compare(a *asm, arg *Arg, ok, fail string) error {
+	hi, lo := uint32(arg.Value>>32), uint32(arg.Value)
+
+	switch arg.Op {
+	case OpEqualTo, OpMaskedEqual:
+		maskHi, maskLo := ^uint32(0), ^uint32(0)
+		if arg.Op == OpMaskedEqual {
+			maskHi, maskLo = uint32(arg.ValueTwo>>32), uint32(arg.ValueTwo)
+		}
+
+		mid := a.newLabel()
+		a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, argOffsetHi(arg.Index))
+		if arg.Op == OpMaskedEqual {
+			a.stmt(unix.BPF_ALU|unix.BPF_AND|unix.BPF_K, maskHi)
+		}
+		a.jumpEq(hi&maskHi, mid, fail)
+		a.label(mid)
+		a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, argOffsetLo(arg.Index))
+		if arg.Op == OpMaskedEqual {
+			a.stmt(unix.BPF_ALU|unix.BPF_AND|unix.BPF_K, maskLo)
+		}
+		a.jumpEq(lo&maskLo, ok, fail)
+
+	case OpNotEqual:
+		mid := a.newLabel()
+		a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, argOffsetHi(arg.Index))
+		a.jumpEq(hi, mid, ok)
+		a.label(mid)
+		a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, argOffsetLo(arg.Index))
+		a.jumpEq(lo, fail, ok)
+
+	case OpGreaterThan, OpGreaterEqual, OpLessThan, OpLessEqual:
+		mid := a.newLabel()
+		neq := a.newLabel()
+		a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, argOffsetHi(arg.Index))
+		a.jumpEq(hi, mid, neq)
+		a.label(neq)
+		// The high words differ, so the result is decided without
+		// looking at the low word: arg>value iff arg's high word is
+		// the bigger one.
+		switch arg.Op {
+		case OpGreaterThan, OpGreaterEqual:
+			a.jumpOp(unix.BPF_JGT, hi, ok, fail)
+		default: // OpLessThan, OpLessEqual
+			a.jumpOp(unix.BPF_JGE, hi, fail, ok)
+		}
+		a.label(mid)
+		a.stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, argOffsetLo(arg.Index))
+		switch arg.Op {
+		case OpGreaterThan:
+			a.jumpOp(unix.BPF_JGT, lo, ok, fail)
+		case OpGreaterEqual:
+			a.jumpOp(unix.BPF_JGE, lo, ok, fail)
+		case OpLessThan:
+			a.jumpOp(unix.BPF_JGE, lo, fail, ok)
+		case OpLessEqual:
+			a.jumpOp(unix.BPF_JGT, lo, fail, ok)
+		}
+
+	default:
+		return errors.Errorf("invalid operator %s", arg.Op)
+	}
+	return nil
+}
+
+// rawAction converts an Action into the raw SECCOMP_RET_* value the
+// kernel expects, matching the semantics of the cgo-based toAction.
+func rawAction(act Action, errnoRet *uint) (uint32, error) {
+	switch act {
+	case ActKill:
+		return unix.SECCOMP_RET_KILL_THREAD, nil
+	case ActKillProcess:
+		return unix.SECCOMP_RET_KILL_PROCESS, nil
+	case ActTrap:
+		return unix.SECCOMP_RET_TRAP, nil
+	case ActErrno:
+		return unix.SECCOMP_RET_ERRNO | (errnoOrDefault(errnoRet) & unix.SECCOMP_RET_DATA), nil
+	case ActTrace:
+		return unix.SECCOMP_RET_TRACE | (errnoOrDefault(errnoRet) & unix.SECCOMP_RET_DATA), nil
+	case ActAllow:
+		return unix.SECCOMP_RET_ALLOW, nil
+	case ActLog:
+		return unix.SECCOMP_RET_LOG, nil
+	case ActNotify:
+		return unix.SECCOMP_RET_USER_NOTIF, nil
+	default:
+		return 0, errors.Errorf("invalid action %s", act)
+	}
+}
+
+func errnoOrDefault(errnoRet *uint) uint32 {
+	if errnoRet != nil {
+		return uint32(*errnoRet)
+	}
+	return uint32(unix.EPERM)
+}
+
+// filterSlice views a compiled *unix.SockFprog as a []unix.SockFilter.
+func filterSlice(prog *unix.SockFprog) []unix.SockFilter {
+	return unsafe.Slice(prog.Filter, int(prog.Len))
+}
+
+// LoadFilter installs prog as the calling thread's seccomp filter via the
+// seccomp(2) syscall, equivalent to libseccomp's ScmpFilter.Load.
+func LoadFilter(prog *unix.SockFprog, flags uint) error {
+	if prog == nil {
+		return errors.New("cannot load nil filter")
+	}
+
+	if _, _, errno := unix.RawSyscall(unix.SYS_SECCOMP,
+		unix.SECCOMP_SET_MODE_FILTER, uintptr(flags), uintptr(unsafe.Pointer(prog))); errno != 0 {
+		return errors.Wrap(errno, "seccomp(SECCOMP_SET_MODE_FILTER)")
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+//go:build seccomp && (!cgo || nativebpf)
+// +build seccomp
+// +build !cgo nativebpf
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// seccompData encodes a synthetic struct seccomp_data for interpretBPF,
+// matching the layout documented on offsetNR and offsetArch.
+func seccompData(arch uint32, nr int32, args [6]uint64) []byte {
+	buf := make([]byte, 16+8*6)
+	binary.LittleEndian.PutUint32(buf[offsetNR:], uint32(nr))
+	binary.LittleEndian.PutUint32(buf[offsetArch:], arch)
+	for i, v := range args {
+		binary.LittleEndian.PutUint64(buf[16+8*i:], v)
+	}
+	return buf
+}
+
+// interpretBPF is a minimal classic-BPF interpreter covering the
+// instructions Compile emits, used to verify the compiled program's
+// actual behavior instead of just its shape.
+func interpretBPF(t *testing.T, prog *unix.SockFprog, data []byte) uint32 {
+	t.Helper()
+
+	filters := filterSlice(prog)
+	var acc uint32
+	pc := 0
+	for steps := 0; ; steps++ {
+		if steps > 10000 {
+			t.Fatalf("interpretBPF: ran too many steps, likely an infinite loop")
+		}
+		if pc < 0 || pc >= len(filters) {
+			t.Fatalf("interpretBPF: pc %d out of range (%d instructions)", pc, len(filters))
+		}
+		in := filters[pc]
+
+		switch in.Code & 0x07 {
+		case unix.BPF_LD:
+			acc = binary.LittleEndian.Uint32(data[in.K : in.K+4])
+			pc++
+		case unix.BPF_ALU:
+			switch in.Code & 0xf0 {
+			case unix.BPF_AND:
+				acc &= in.K
+			default:
+				t.Fatalf("interpretBPF: unsupported ALU op %#x", in.Code)
+			}
+			pc++
+		case unix.BPF_JMP:
+			if in.Code&0xf0 == unix.BPF_JA {
+				pc += 1 + int(in.K)
+				continue
+			}
+
+			var taken bool
+			switch in.Code & 0xf0 {
+			case unix.BPF_JEQ:
+				taken = acc == in.K
+			case unix.BPF_JGT:
+				taken = acc > in.K
+			case unix.BPF_JGE:
+				taken = acc >= in.K
+			default:
+				t.Fatalf("interpretBPF: unsupported JMP op %#x", in.Code)
+			}
+
+			if taken {
+				pc += 1 + int(in.Jt)
+			} else {
+				pc += 1 + int(in.Jf)
+			}
+		case unix.BPF_RET:
+			return in.K
+		default:
+			t.Fatalf("interpretBPF: unsupported instruction class %#x", in.Code)
+		}
+	}
+}
+
+func TestCompileInterpretedAllowAndErrno(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "write", Action: ActAllow},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+
+	audit, _, err := nativeArch()
+	if err != nil {
+		t.Fatalf("nativeArch() returned error: %v", err)
+	}
+
+	readNR, _ := syscallNumber("read")
+	writeNR, _ := syscallNumber("write")
+	closeNR, _ := syscallNumber("close")
+
+	if got := interpretBPF(t, prog, seccompData(audit, readNR, [6]uint64{})); got != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("read: expected SECCOMP_RET_ALLOW, got %#x", got)
+	}
+	if got := interpretBPF(t, prog, seccompData(audit, writeNR, [6]uint64{})); got != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("write: expected SECCOMP_RET_ALLOW, got %#x", got)
+	}
+	if got := interpretBPF(t, prog, seccompData(audit, closeNR, [6]uint64{})); got != unix.SECCOMP_RET_ERRNO|(uint32(unix.EPERM)&unix.SECCOMP_RET_DATA) {
+		t.Fatalf("close: expected default SECCOMP_RET_ERRNO, got %#x", got)
+	}
+	if got := interpretBPF(t, prog, seccompData(audit^0xffff, readNR, [6]uint64{})); got != unix.SECCOMP_RET_KILL_THREAD {
+		t.Fatalf("foreign arch: expected SECCOMP_RET_KILL_THREAD, got %#x", got)
+	}
+}
+
+// TestCompileInterpretedSharedActionTail guards against the dedup in
+// Compile reusing an earlier syscall's shared RET as a later syscall's
+// jump target: classic BPF jt/jf are unsigned forward offsets, so every
+// syscall sharing a bare action - not just the first one checked - must
+// still reach that RET correctly.
+func TestCompileInterpretedSharedActionTail(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "write", Action: ActAllow},
+			{Name: "close", Action: ActAllow},
+			{Name: "fstat", Action: ActAllow},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+
+	audit, _, err := nativeArch()
+	if err != nil {
+		t.Fatalf("nativeArch() returned error: %v", err)
+	}
+
+	for _, name := range []string{"read", "write", "close", "fstat"} {
+		nr, ok := syscallNumber(name)
+		if !ok {
+			t.Fatalf("syscallNumber(%q) not found", name)
+		}
+		if got := interpretBPF(t, prog, seccompData(audit, nr, [6]uint64{})); got != unix.SECCOMP_RET_ALLOW {
+			t.Fatalf("%s: expected SECCOMP_RET_ALLOW, got %#x", name, got)
+		}
+	}
+
+	mmapNR, _ := syscallNumber("mmap")
+	if got := interpretBPF(t, prog, seccompData(audit, mmapNR, [6]uint64{})); got != unix.SECCOMP_RET_ERRNO|(uint32(unix.EPERM)&unix.SECCOMP_RET_DATA) {
+		t.Fatalf("mmap: expected default SECCOMP_RET_ERRNO, got %#x", got)
+	}
+}
+
+func TestCompileInterpretedArgMatch(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{
+				Name:   "mount",
+				Action: ActAllow,
+				Args: []*Arg{
+					{Index: 3, Op: OpEqualTo, Value: 42},
+				},
+			},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+
+	audit, _, err := nativeArch()
+	if err != nil {
+		t.Fatalf("nativeArch() returned error: %v", err)
+	}
+
+	mountNR, _ := syscallNumber("mount")
+
+	matching := [6]uint64{0, 0, 0, 42}
+	if got := interpretBPF(t, prog, seccompData(audit, mountNR, matching)); got != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("matching arg: expected SECCOMP_RET_ALLOW, got %#x", got)
+	}
+
+	mismatching := [6]uint64{0, 0, 0, 7}
+	if got := interpretBPF(t, prog, seccompData(audit, mountNR, mismatching)); got != unix.SECCOMP_RET_ERRNO|(uint32(unix.EPERM)&unix.SECCOMP_RET_DATA) {
+		t.Fatalf("mismatching arg: expected default SECCOMP_RET_ERRNO, got %#x", got)
+	}
+}
+
+// TestCompileInterpretedArgMatchHighWord guards against only comparing an
+// argument's low 32 bits: a value whose low word happens to match the
+// rule but whose high word doesn't must not be treated as a match.
+func TestCompileInterpretedArgMatchHighWord(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{
+				Name:   "mount",
+				Action: ActAllow,
+				Args: []*Arg{
+					{Index: 3, Op: OpEqualTo, Value: 0},
+				},
+			},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+
+	audit, _, err := nativeArch()
+	if err != nil {
+		t.Fatalf("nativeArch() returned error: %v", err)
+	}
+
+	mountNR, _ := syscallNumber("mount")
+
+	// Low word is 0 (matching arg.Value), but the high word is set, so
+	// the true 64-bit argument (0x100000000) is not 0 and must not be
+	// allowed.
+	highWordSet := [6]uint64{0, 0, 0, 0x100000000}
+	if got := interpretBPF(t, prog, seccompData(audit, mountNR, highWordSet)); got == unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("arg3=0x100000000 matched a ==0 rule: high word of the argument was not checked")
+	}
+}
@@ -0,0 +1,105 @@
+//go:build seccomp && (!cgo || nativebpf)
+// +build seccomp
+// +build !cgo nativebpf
+
+package seccomp
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCompileAllowsDefaultAction(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActAllow,
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+	if prog == nil || prog.Len == 0 {
+		t.Fatalf("Compile() returned an empty program")
+	}
+}
+
+func TestCompileAllowListWithErrnoDefault(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "write", Action: ActAllow},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+	if prog.Len == 0 {
+		t.Fatalf("Compile() returned an empty program")
+	}
+}
+
+func TestCompileRejectsForeignArchitecture(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActAllow,
+		Architectures: []Arch{ArchPPC64LE},
+	}
+
+	if _, err := Compile(profile); err == nil {
+		t.Fatalf("expected an error for a profile missing the runtime architecture")
+	}
+}
+
+func TestCompileWithArgConditions(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{
+				Name:   "mount",
+				Action: ActAllow,
+				Args: []*Arg{
+					{Index: 3, Op: OpMaskedEqual, Value: 0, ValueTwo: 0xffffffff},
+				},
+			},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+	if prog.Len == 0 {
+		t.Fatalf("Compile() returned an empty program")
+	}
+}
+
+func TestCompileDedupesIdenticalActionTails(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "write", Action: ActAllow},
+			{Name: "close", Action: ActAllow},
+		},
+	}
+
+	prog, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile() returned error: %v", err)
+	}
+
+	rets := 0
+	for _, f := range filterSlice(prog) {
+		if f.Code&0x07 == unix.BPF_RET {
+			rets++
+		}
+	}
+	// bad-arch, allow and default-action tails: exactly three RET
+	// instructions regardless of how many syscalls share the ALLOW action.
+	if rets != 3 {
+		t.Fatalf("expected 3 RET instructions, got %d", rets)
+	}
+}
@@ -1,4 +1,5 @@
-// +build seccomp
+//go:build seccomp && cgo
+// +build seccomp,cgo
 
 package seccomp
 
@@ -11,7 +12,21 @@ import (
 // BuildFilter does a basic validation for the provided seccomp profile
 // string and returns a filter for it.
 func BuildFilter(profile *Seccomp) (*libseccomp.ScmpFilter, error) {
-	defaultAction, err := toAction(profile.DefaultAction, nil)
+	return BuildFilterWithOptions(profile, nil)
+}
+
+// BuildFilterWithOptions is BuildFilter with additional, optional builder
+// behavior; a nil opts behaves exactly like BuildFilter.
+func BuildFilterWithOptions(profile *Seccomp, opts *BuilderOptions) (*libseccomp.ScmpFilter, error) {
+	if opts != nil && opts.AutoDowngrade {
+		caps, err := GetSupported()
+		if err != nil {
+			return nil, errors.Wrap(err, "probe supported seccomp capabilities")
+		}
+		profile, _ = Downgrade(profile, *caps)
+	}
+
+	defaultAction, err := toAction(profile.DefaultAction, profile.DefaultErrnoRet)
 	if err != nil {
 		return nil, errors.Wrapf(err, "convert default action %s", profile.DefaultAction)
 	}
@@ -21,6 +36,11 @@ func BuildFilter(profile *Seccomp) (*libseccomp.ScmpFilter, error) {
 		return nil, errors.Wrapf(err, "create filter for default action %s", defaultAction)
 	}
 
+	var hook Hook
+	if opts != nil {
+		hook = opts.Hook
+	}
+
 	// Add extra architectures
 	for _, arch := range profile.Architectures {
 		scmpArch, err := libseccomp.GetArchFromString(string(arch))
@@ -31,6 +51,7 @@ func BuildFilter(profile *Seccomp) (*libseccomp.ScmpFilter, error) {
 		if err := filter.AddArch(scmpArch); err != nil {
 			return nil, errors.Wrap(err, "add architecture to seccomp filter")
 		}
+		notify(hook, Event{Kind: EventArchAdded, Arch: arch})
 	}
 
 	// Unset no new privs bit
@@ -44,7 +65,7 @@ func BuildFilter(profile *Seccomp) (*libseccomp.ScmpFilter, error) {
 			return nil, errors.New("encountered nil syscall while initializing seccomp")
 		}
 
-		if err = matchSyscall(filter, call); err != nil {
+		if err = matchSyscall(filter, call, hook); err != nil {
 			return nil, errors.Wrap(err, "filter matches syscall")
 		}
 	}
@@ -52,7 +73,14 @@ func BuildFilter(profile *Seccomp) (*libseccomp.ScmpFilter, error) {
 	return filter, nil
 }
 
-func matchSyscall(filter *libseccomp.ScmpFilter, call *Syscall) error {
+// notify calls hook.Handle(ev) if hook is non-nil.
+func notify(hook Hook, ev Event) {
+	if hook != nil {
+		hook.Handle(ev)
+	}
+}
+
+func matchSyscall(filter *libseccomp.ScmpFilter, call *Syscall, hook Hook) error {
 	if call == nil || filter == nil {
 		return errors.New("cannot use nil as syscall to block")
 	}
@@ -65,6 +93,7 @@ func matchSyscall(filter *libseccomp.ScmpFilter, call *Syscall) error {
 	// Ignore it, don't error out
 	callNum, err := libseccomp.GetSyscallFromName(call.Name)
 	if err != nil {
+		notify(hook, Event{Kind: EventSyscallSkipped, Syscall: call.Name, Detail: err.Error()})
 		return nil
 	}
 
@@ -79,6 +108,7 @@ func matchSyscall(filter *libseccomp.ScmpFilter, call *Syscall) error {
 		if err = filter.AddRule(callNum, callAct); err != nil {
 			return errors.Wrapf(err, "add seccomp filter rule for syscall %s", call.Name)
 		}
+		notify(hook, Event{Kind: EventRuleAdded, Syscall: call.Name})
 	} else {
 		// Linux system calls can have at most 6 arguments
 		const syscallMaxArguments int = 6
@@ -110,6 +140,11 @@ func matchSyscall(filter *libseccomp.ScmpFilter, call *Syscall) error {
 		if hasMultipleArgs {
 			// Revert to old behavior
 			// Add each condition attached to a separate rule
+			notify(hook, Event{
+				Kind:    EventMultipleArgsFallback,
+				Syscall: call.Name,
+				Detail:  "multiple conditions on the same argument index",
+			})
 			for _, cond := range conditions {
 				condArr := []libseccomp.ScmpCondition{cond}
 
@@ -124,6 +159,7 @@ func matchSyscall(filter *libseccomp.ScmpFilter, call *Syscall) error {
 				return errors.Wrapf(err, "add seccomp rule for syscall %s", call.Name)
 			}
 		}
+		notify(hook, Event{Kind: EventRuleAdded, Syscall: call.Name})
 	}
 
 	return nil
@@ -153,6 +189,8 @@ func toAction(act Action, errnoRet *uint) (libseccomp.ScmpAction, error) {
 		return libseccomp.ActTrace.SetReturnCode(int16(unix.EPERM)), nil
 	case ActLog:
 		return libseccomp.ActLog, nil
+	case ActNotify:
+		return libseccomp.ActNotify, nil
 	default:
 		return libseccomp.ActInvalid, errors.Errorf("invalid action %s", act)
 	}
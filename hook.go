@@ -0,0 +1,80 @@
+package seccomp
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// EventKind identifies what a Hook is being told about.
+type EventKind int
+
+const (
+	// EventSyscallSkipped fires when a syscall name in the profile
+	// couldn't be resolved on the running kernel and was silently
+	// dropped from the filter.
+	EventSyscallSkipped EventKind = iota
+	// EventRuleAdded fires once a rule for a syscall has been added to
+	// the filter.
+	EventRuleAdded
+	// EventArchAdded fires when an additional architecture is added to
+	// the filter.
+	EventArchAdded
+	// EventMultipleArgsFallback fires when a syscall rule has more than
+	// one Args condition on the same argument index, forcing the
+	// per-condition fallback instead of a single combined rule.
+	EventMultipleArgsFallback
+)
+
+// Event describes one occurrence a Hook is notified about while a filter
+// is being built.
+type Event struct {
+	Kind    EventKind
+	Syscall string
+	Arch    Arch
+	Detail  string
+}
+
+// Hook receives Events while BuildFilterWithOptions builds a filter. The
+// zero value of most Hook implementations should be safe to use; a nil
+// Hook is never called.
+type Hook interface {
+	Handle(Event)
+}
+
+// SlogHook is the default Hook implementation, logging every Event
+// through a *slog.Logger.
+type SlogHook struct {
+	Logger *slog.Logger
+}
+
+// Handle implements Hook.
+func (h SlogHook) Handle(ev Event) {
+	logger := h.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	switch ev.Kind {
+	case EventSyscallSkipped:
+		logger.Warn("skipping unresolvable syscall", "syscall", ev.Syscall, "detail", ev.Detail)
+	case EventRuleAdded:
+		logger.Debug("added seccomp rule", "syscall", ev.Syscall, "detail", ev.Detail)
+	case EventArchAdded:
+		logger.Debug("added seccomp architecture", "arch", ev.Arch)
+	case EventMultipleArgsFallback:
+		logger.Debug("falling back to per-condition rules", "syscall", ev.Syscall, "detail", ev.Detail)
+	default:
+		logger.Warn("unknown seccomp build event", "kind", fmt.Sprint(ev.Kind))
+	}
+}
+
+// RecorderHook is a Hook that simply records every Event it receives, for
+// use in tests.
+type RecorderHook struct {
+	Events []Event
+}
+
+// Handle implements Hook.
+func (h *RecorderHook) Handle(ev Event) {
+	h.Events = append(h.Events, ev)
+}
@@ -0,0 +1,37 @@
+//go:build seccomp && cgo
+// +build seccomp,cgo
+
+package seccomp
+
+import "testing"
+
+func TestBuildFilterWithOptionsNotifiesHook(t *testing.T) {
+	recorder := &RecorderHook{}
+	profile := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "this-syscall-does-not-exist", Action: ActAllow},
+		},
+	}
+
+	if _, err := BuildFilterWithOptions(profile, &BuilderOptions{Hook: recorder}); err != nil {
+		t.Fatalf("BuildFilterWithOptions() returned error: %v", err)
+	}
+
+	var sawRuleAdded, sawSkipped bool
+	for _, ev := range recorder.Events {
+		switch ev.Kind {
+		case EventRuleAdded:
+			sawRuleAdded = true
+		case EventSyscallSkipped:
+			sawSkipped = true
+		}
+	}
+	if !sawRuleAdded {
+		t.Fatalf("expected an EventRuleAdded event, got %+v", recorder.Events)
+	}
+	if !sawSkipped {
+		t.Fatalf("expected an EventSyscallSkipped event, got %+v", recorder.Events)
+	}
+}
@@ -0,0 +1,247 @@
+//go:build seccomp
+// +build seccomp
+
+package seccomp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Merge composes base with overlays applied in order, so callers can build
+// a profile out of a shared base (e.g. the containers/common default) and
+// small allow/deny overlays instead of hand-editing JSON.
+//
+// Later overlays win: a non-empty DefaultAction or Architectures list in
+// an overlay replaces the one accumulated so far. Per-syscall rules with
+// the same (Name, Action, Args) as an existing rule are deduped; rules
+// that share a Name but differ in Args are concatenated; rules that share
+// a Name but specify a different Action replace the existing ones for
+// that syscall, since the two can't both apply.
+func Merge(base *Seccomp, overlays ...*Seccomp) (*Seccomp, error) {
+	if base == nil {
+		return nil, errors.New("cannot merge nil base profile")
+	}
+
+	merged := &Seccomp{
+		DefaultAction:   base.DefaultAction,
+		DefaultErrnoRet: copyUintPtr(base.DefaultErrnoRet),
+		Architectures:   append([]Arch(nil), base.Architectures...),
+	}
+	for _, call := range base.Syscalls {
+		merged.Syscalls = mergeSyscall(merged.Syscalls, call)
+	}
+
+	for _, overlay := range overlays {
+		if overlay == nil {
+			continue
+		}
+
+		if overlay.DefaultAction != "" {
+			merged.DefaultAction = overlay.DefaultAction
+			merged.DefaultErrnoRet = copyUintPtr(overlay.DefaultErrnoRet)
+		}
+		if len(overlay.Architectures) > 0 {
+			merged.Architectures = append([]Arch(nil), overlay.Architectures...)
+		}
+		for _, call := range overlay.Syscalls {
+			merged.Syscalls = mergeSyscall(merged.Syscalls, call)
+		}
+	}
+
+	if err := validateProfileStruct(merged); err != nil {
+		return nil, errors.Wrap(err, "validate merged profile")
+	}
+
+	return merged, nil
+}
+
+// mergeSyscall folds call into syscalls per the conflict rules documented
+// on Merge.
+func mergeSyscall(syscalls []*Syscall, call *Syscall) []*Syscall {
+	if call == nil {
+		return syscalls
+	}
+
+	sameName := -1
+	for i, existing := range syscalls {
+		if existing.Name != call.Name {
+			continue
+		}
+		if existing.Action == call.Action {
+			if argsKey(existing.Args) == argsKey(call.Args) {
+				// Exact duplicate: nothing to do.
+				return syscalls
+			}
+			// Same name and action, different args: concatenate.
+			continue
+		}
+		sameName = i
+	}
+
+	if sameName >= 0 {
+		// Conflicting action for the same syscall: the overlay wins, so
+		// drop every existing rule for this syscall before adding call.
+		kept := syscalls[:0]
+		for _, existing := range syscalls {
+			if existing.Name != call.Name {
+				kept = append(kept, existing)
+			}
+		}
+		syscalls = kept
+	}
+
+	return append(syscalls, call)
+}
+
+// Diff produces the minimal overlay that, applied to a via Merge, turns it
+// into b: a DefaultAction/Architectures change only if they differ, and
+// only the syscall rules present in b but not in a.
+//
+// Merge has no delete semantics, so Diff can only express b adding or
+// replacing rules for a syscall, never b narrowing or dropping a rule a
+// already had while keeping the same action; Diff errors in that case
+// instead of silently returning an overlay that doesn't reproduce b. It
+// likewise errors when b has no Architectures but a has some.
+func Diff(a, b *Seccomp) (*Seccomp, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("cannot diff nil profile")
+	}
+
+	overlay := &Seccomp{}
+
+	sameErrnoRet := (a.DefaultErrnoRet == nil && b.DefaultErrnoRet == nil) ||
+		(a.DefaultErrnoRet != nil && b.DefaultErrnoRet != nil && *a.DefaultErrnoRet == *b.DefaultErrnoRet)
+	if a.DefaultAction != b.DefaultAction || !sameErrnoRet {
+		overlay.DefaultAction = b.DefaultAction
+		overlay.DefaultErrnoRet = copyUintPtr(b.DefaultErrnoRet)
+	}
+
+	if !archesEqual(a.Architectures, b.Architectures) {
+		if len(b.Architectures) == 0 {
+			return nil, errors.New("cannot diff: b drops all of a's architectures, which Merge cannot express")
+		}
+		overlay.Architectures = append([]Arch(nil), b.Architectures...)
+	}
+
+	if err := checkNoUnrepresentableRemovals(a, b); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(a.Syscalls))
+	for _, call := range a.Syscalls {
+		existing[syscallKey(call)] = true
+	}
+	for _, call := range b.Syscalls {
+		if !existing[syscallKey(call)] {
+			overlay.Syscalls = append(overlay.Syscalls, call)
+		}
+	}
+
+	if _, err := Merge(a, overlay); err != nil {
+		return nil, errors.Wrap(err, "validate diff overlay")
+	}
+
+	return overlay, nil
+}
+
+// checkNoUnrepresentableRemovals rejects (a, b) pairs where b drops or
+// narrows a rule a has for a syscall while keeping the same action for
+// it: mergeSyscall only ever adds rules for a (name, action) pair or
+// replaces them wholesale on a differing action, so there is no overlay
+// that can reproduce b in that case.
+func checkNoUnrepresentableRemovals(a, b *Seccomp) error {
+	aByName := map[string][]string{}
+	for _, call := range a.Syscalls {
+		if call == nil {
+			continue
+		}
+		aByName[call.Name] = append(aByName[call.Name], string(call.Action)+"\x00"+argsKey(call.Args))
+	}
+
+	bByName := map[string]map[string]bool{}
+	bActionsByName := map[string]map[Action]bool{}
+	for _, call := range b.Syscalls {
+		if call == nil {
+			continue
+		}
+		if bByName[call.Name] == nil {
+			bByName[call.Name] = map[string]bool{}
+			bActionsByName[call.Name] = map[Action]bool{}
+		}
+		bByName[call.Name][string(call.Action)+"\x00"+argsKey(call.Args)] = true
+		bActionsByName[call.Name][call.Action] = true
+	}
+
+	for name, aKeys := range aByName {
+		bActions, ok := bActionsByName[name]
+		if !ok {
+			// b has no rules at all for a syscall a has: a full removal,
+			// which an additive overlay can never express.
+			return errors.Errorf("cannot diff: syscall %s is removed entirely in b, which Merge cannot express", name)
+		}
+
+		bKeys := bByName[name]
+		for _, key := range aKeys {
+			if bKeys[key] {
+				continue
+			}
+			// Missing from b. That's fine if b replaces the rule with a
+			// different action for this syscall - mergeSyscall drops all
+			// of a's existing rules for name in that case. Otherwise b
+			// keeps the same action for this syscall but drops or
+			// narrows a rule, which no overlay can express.
+			action := Action(key[:strings.IndexByte(key, '\x00')])
+			if bActions[action] {
+				return errors.Errorf("cannot diff: syscall %s keeps action %s in b but drops a rule for it, which Merge cannot express", name, action)
+			}
+		}
+	}
+
+	return nil
+}
+
+func syscallKey(call *Syscall) string {
+	if call == nil {
+		return ""
+	}
+	return call.Name + "\x00" + string(call.Action) + "\x00" + argsKey(call.Args)
+}
+
+func argsKey(args []*Arg) string {
+	b, _ := json.Marshal(args)
+	return string(b)
+}
+
+func archesEqual(a, b []Arch) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func copyUintPtr(v *uint) *uint {
+	if v == nil {
+		return nil
+	}
+	u := *v
+	return &u
+}
+
+// validateProfileStruct is ValidateProfile for an already-parsed profile,
+// used internally so Merge and Diff can confirm the profiles they build
+// are still buildable.
+func validateProfileStruct(profile *Seccomp) error {
+	content, err := json.Marshal(profile)
+	if err != nil {
+		return errors.Wrap(err, "encoding seccomp profile")
+	}
+	return ValidateProfile(string(content))
+}
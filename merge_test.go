@@ -0,0 +1,113 @@
+//go:build seccomp
+// +build seccomp
+
+package seccomp
+
+import "testing"
+
+func TestMergeDedupesIdenticalRules(t *testing.T) {
+	base := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls:      []*Syscall{{Name: "read", Action: ActAllow}},
+	}
+	overlay := &Seccomp{
+		Syscalls: []*Syscall{{Name: "read", Action: ActAllow}},
+	}
+
+	merged, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+	if len(merged.Syscalls) != 1 {
+		t.Fatalf("expected 1 syscall rule after dedup, got %d", len(merged.Syscalls))
+	}
+}
+
+func TestMergeOverlayWinsOnConflict(t *testing.T) {
+	base := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls:      []*Syscall{{Name: "ptrace", Action: ActErrno}},
+	}
+	overlay := &Seccomp{
+		Syscalls: []*Syscall{{Name: "ptrace", Action: ActAllow}},
+	}
+
+	merged, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+	if len(merged.Syscalls) != 1 || merged.Syscalls[0].Action != ActAllow {
+		t.Fatalf("expected overlay's ActAllow rule to win, got %+v", merged.Syscalls)
+	}
+}
+
+func TestMergeConcatenatesDifferingArgs(t *testing.T) {
+	base := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{{
+			Name:   "mount",
+			Action: ActAllow,
+			Args:   []*Arg{{Index: 3, Op: OpEqualTo, Value: 0}},
+		}},
+	}
+	overlay := &Seccomp{
+		Syscalls: []*Syscall{{
+			Name:   "mount",
+			Action: ActAllow,
+			Args:   []*Arg{{Index: 3, Op: OpEqualTo, Value: 1}},
+		}},
+	}
+
+	merged, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+	if len(merged.Syscalls) != 2 {
+		t.Fatalf("expected both mount rules to be kept, got %d", len(merged.Syscalls))
+	}
+}
+
+func TestDiffProducesApplicableOverlay(t *testing.T) {
+	a := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls:      []*Syscall{{Name: "read", Action: ActAllow}},
+	}
+	b := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "write", Action: ActAllow},
+		},
+	}
+
+	overlay, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("Diff() returned error: %v", err)
+	}
+
+	merged, err := Merge(a, overlay)
+	if err != nil {
+		t.Fatalf("Merge(a, overlay) returned error: %v", err)
+	}
+	if len(merged.Syscalls) != 2 {
+		t.Fatalf("expected the diff overlay to reproduce b, got %+v", merged.Syscalls)
+	}
+}
+
+func TestDiffErrorsWhenBRemovesARule(t *testing.T) {
+	a := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls: []*Syscall{
+			{Name: "read", Action: ActAllow},
+			{Name: "write", Action: ActAllow},
+		},
+	}
+	b := &Seccomp{
+		DefaultAction: ActErrno,
+		Syscalls:      []*Syscall{{Name: "read", Action: ActAllow}},
+	}
+
+	if _, err := Diff(a, b); err == nil {
+		t.Fatalf("expected Diff() to error when b drops a's \"write\" rule, since Merge can't express a removal")
+	}
+}
@@ -0,0 +1,217 @@
+//go:build seccomp
+// +build seccomp
+
+package seccomp
+
+import (
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// ToOCI converts profile into the equivalent OCI runtime-spec
+// LinuxSeccomp, so that callers building an OCI runtime config (podman,
+// cri-o, runtime-tools' generate) don't need to re-implement the mapping
+// themselves.
+func ToOCI(profile *Seccomp) (*specs.LinuxSeccomp, error) {
+	if profile == nil {
+		return nil, errors.New("cannot convert nil profile")
+	}
+
+	ociAction, err := toOCIAction(profile.DefaultAction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "convert default action %s", profile.DefaultAction)
+	}
+
+	oci := &specs.LinuxSeccomp{
+		DefaultAction:   ociAction,
+		DefaultErrnoRet: copyUintPtr(profile.DefaultErrnoRet),
+	}
+
+	for _, arch := range profile.Architectures {
+		oci.Architectures = append(oci.Architectures, specs.Arch(arch))
+	}
+
+	for _, call := range profile.Syscalls {
+		if call == nil {
+			return nil, errors.New("encountered nil syscall while converting profile")
+		}
+
+		ociCallAction, err := toOCIAction(call.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "convert action %s for syscall %s", call.Action, call.Name)
+		}
+
+		ociSyscall := specs.LinuxSyscall{
+			Names:    []string{call.Name},
+			Action:   ociCallAction,
+			ErrnoRet: copyUintPtr(call.ErrnoRet),
+		}
+
+		for _, arg := range call.Args {
+			if arg == nil {
+				return nil, errors.New("encountered nil argument while converting profile")
+			}
+
+			ociOp, err := toOCIOperator(arg.Op)
+			if err != nil {
+				return nil, errors.Wrapf(err, "convert operator for syscall %s", call.Name)
+			}
+
+			ociSyscall.Args = append(ociSyscall.Args, specs.LinuxSeccompArg{
+				Index:    arg.Index,
+				Value:    arg.Value,
+				ValueTwo: arg.ValueTwo,
+				Op:       ociOp,
+			})
+		}
+
+		oci.Syscalls = append(oci.Syscalls, ociSyscall)
+	}
+
+	return oci, nil
+}
+
+// FromOCI converts an OCI runtime-spec LinuxSeccomp into this package's
+// Seccomp type, the inverse of ToOCI.
+func FromOCI(oci *specs.LinuxSeccomp) (*Seccomp, error) {
+	if oci == nil {
+		return nil, errors.New("cannot convert nil OCI seccomp config")
+	}
+
+	action, err := fromOCIAction(oci.DefaultAction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "convert default action %s", oci.DefaultAction)
+	}
+
+	profile := &Seccomp{
+		DefaultAction:   action,
+		DefaultErrnoRet: copyUintPtr(oci.DefaultErrnoRet),
+	}
+
+	for _, arch := range oci.Architectures {
+		profile.Architectures = append(profile.Architectures, Arch(arch))
+	}
+
+	for _, call := range oci.Syscalls {
+		callAction, err := fromOCIAction(call.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "convert action %s for syscalls %v", call.Action, call.Names)
+		}
+
+		var args []*Arg
+		for _, ociArg := range call.Args {
+			op, err := fromOCIOperator(ociArg.Op)
+			if err != nil {
+				return nil, errors.Wrapf(err, "convert operator for syscalls %v", call.Names)
+			}
+
+			args = append(args, &Arg{
+				Index:    ociArg.Index,
+				Value:    ociArg.Value,
+				ValueTwo: ociArg.ValueTwo,
+				Op:       op,
+			})
+		}
+
+		// A single OCI LinuxSyscall entry can name more than one syscall;
+		// this package matches one name per rule, so fan it back out.
+		for _, name := range call.Names {
+			profile.Syscalls = append(profile.Syscalls, &Syscall{
+				Name:     name,
+				Action:   callAction,
+				Args:     args,
+				ErrnoRet: copyUintPtr(call.ErrnoRet),
+			})
+		}
+	}
+
+	return profile, nil
+}
+
+func toOCIAction(act Action) (specs.LinuxSeccompAction, error) {
+	switch act {
+	case ActKill:
+		return specs.ActKill, nil
+	case ActKillProcess:
+		return specs.ActKillProcess, nil
+	case ActTrap:
+		return specs.ActTrap, nil
+	case ActErrno:
+		return specs.ActErrno, nil
+	case ActTrace:
+		return specs.ActTrace, nil
+	case ActAllow:
+		return specs.ActAllow, nil
+	case ActLog:
+		return specs.ActLog, nil
+	case ActNotify:
+		return specs.ActNotify, nil
+	default:
+		return "", errors.Errorf("invalid action %s", act)
+	}
+}
+
+func fromOCIAction(act specs.LinuxSeccompAction) (Action, error) {
+	switch act {
+	case specs.ActKill:
+		return ActKill, nil
+	case specs.ActKillProcess:
+		return ActKillProcess, nil
+	case specs.ActTrap:
+		return ActTrap, nil
+	case specs.ActErrno:
+		return ActErrno, nil
+	case specs.ActTrace:
+		return ActTrace, nil
+	case specs.ActAllow:
+		return ActAllow, nil
+	case specs.ActLog:
+		return ActLog, nil
+	case specs.ActNotify:
+		return ActNotify, nil
+	default:
+		return "", errors.Errorf("invalid OCI seccomp action %s", act)
+	}
+}
+
+func toOCIOperator(op Operator) (specs.LinuxSeccompOperator, error) {
+	switch op {
+	case OpEqualTo:
+		return specs.OpEqualTo, nil
+	case OpNotEqual:
+		return specs.OpNotEqual, nil
+	case OpGreaterThan:
+		return specs.OpGreaterThan, nil
+	case OpGreaterEqual:
+		return specs.OpGreaterEqual, nil
+	case OpLessThan:
+		return specs.OpLessThan, nil
+	case OpLessEqual:
+		return specs.OpLessEqual, nil
+	case OpMaskedEqual:
+		return specs.OpMaskedEqual, nil
+	default:
+		return "", errors.Errorf("invalid operator %s", op)
+	}
+}
+
+func fromOCIOperator(op specs.LinuxSeccompOperator) (Operator, error) {
+	switch op {
+	case specs.OpEqualTo:
+		return OpEqualTo, nil
+	case specs.OpNotEqual:
+		return OpNotEqual, nil
+	case specs.OpGreaterThan:
+		return OpGreaterThan, nil
+	case specs.OpGreaterEqual:
+		return OpGreaterEqual, nil
+	case specs.OpLessThan:
+		return OpLessThan, nil
+	case specs.OpLessEqual:
+		return OpLessEqual, nil
+	case specs.OpMaskedEqual:
+		return OpMaskedEqual, nil
+	default:
+		return "", errors.Errorf("invalid OCI seccomp operator %s", op)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build seccomp
+// +build seccomp
+
+package seccomp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testProfileJSON = `{
+	"defaultAction": "SCMP_ACT_ERRNO",
+	"architectures": ["SCMP_ARCH_X86_64"],
+	"syscalls": [
+		{"name": "read", "action": "SCMP_ACT_ALLOW"},
+		{"name": "write", "action": "SCMP_ACT_ALLOW"},
+		{
+			"name": "mount",
+			"action": "SCMP_ACT_ALLOW",
+			"args": [{"index": 3, "value": 0, "valueTwo": 4294967295, "op": "SCMP_CMP_MASKED_EQ"}]
+		}
+	]
+}`
+
+func TestToOCIFromOCIRoundTrip(t *testing.T) {
+	profile := &Seccomp{}
+	if err := json.Unmarshal([]byte(testProfileJSON), profile); err != nil {
+		t.Fatalf("unmarshal test profile: %v", err)
+	}
+
+	oci, err := ToOCI(profile)
+	if err != nil {
+		t.Fatalf("ToOCI() returned error: %v", err)
+	}
+
+	roundTripped, err := FromOCI(oci)
+	if err != nil {
+		t.Fatalf("FromOCI() returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(profile, roundTripped) {
+		t.Fatalf("round trip mismatch:\n  before: %+v\n  after:  %+v", profile, roundTripped)
+	}
+}
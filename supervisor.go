@@ -0,0 +1,110 @@
+//go:build seccomp && cgo
+// +build seccomp,cgo
+
+package seccomp
+
+import (
+	"github.com/pkg/errors"
+	libseccomp "github.com/seccomp/libseccomp-golang"
+)
+
+// RespFlagContinue tells the kernel to run the syscall normally after a
+// notification response, equivalent to SECCOMP_USER_NOTIF_FLAG_CONTINUE.
+const RespFlagContinue = libseccomp.NotifRespFlagContinue
+
+// Response is what a Handler returns for a received notification; it is
+// written back to the kernel via NotifRespond.
+type Response struct {
+	Val   uint64
+	Errno int32
+	Flags uint32
+}
+
+// Handler decides how to service one notified syscall.
+type Handler func(req *libseccomp.ScmpNotifReq) (*Response, error)
+
+// Supervisor receives SECCOMP_RET_USER_NOTIF notifications for a filter
+// built with ActNotify rules and dispatches them to Handlers registered by
+// syscall name, so an unprivileged process can emulate syscalls like
+// mount, chroot or device opens on its tracee's behalf.
+type Supervisor struct {
+	fd       libseccomp.ScmpFd
+	handlers map[string]Handler
+}
+
+// NewSupervisor creates a Supervisor for filter, which must already have
+// been loaded with Load() so its notification fd is valid.
+func NewSupervisor(filter *libseccomp.ScmpFilter) (*Supervisor, error) {
+	if filter == nil {
+		return nil, errors.New("cannot supervise a nil filter")
+	}
+
+	fd, err := filter.GetNotifFd()
+	if err != nil {
+		return nil, errors.Wrap(err, "get seccomp notification fd")
+	}
+
+	return &Supervisor{
+		fd:       fd,
+		handlers: map[string]Handler{},
+	}, nil
+}
+
+// Handle registers a Handler for syscall. Registering a second Handler for
+// the same name replaces the first.
+func (s *Supervisor) Handle(syscall string, handler Handler) {
+	s.handlers[syscall] = handler
+}
+
+// Run receives notifications and dispatches each to its registered
+// Handler, returning the first error from receiving a notification, from
+// a Handler, or from responding to the kernel. A syscall with no registered Handler is
+// allowed to proceed via RespFlagContinue. Run blocks the calling
+// goroutine; run it in its own goroutine alongside the supervised
+// process.
+func (s *Supervisor) Run() error {
+	for {
+		req, err := libseccomp.NotifReceive(s.fd)
+		if err != nil {
+			return errors.Wrap(err, "receive seccomp notification")
+		}
+
+		if err := libseccomp.NotifIDValid(s.fd, req.ID); err != nil {
+			// The notifying process has already moved on (e.g. it was
+			// killed); nothing left to respond to.
+			continue
+		}
+
+		resp, err := s.dispatch(req)
+		if err != nil {
+			return errors.Wrap(err, "dispatch seccomp notification")
+		}
+
+		if err := libseccomp.NotifRespond(s.fd, &libseccomp.ScmpNotifResp{
+			ID:    req.ID,
+			Val:   resp.Val,
+			Error: resp.Errno,
+			Flags: resp.Flags,
+		}); err != nil {
+			return errors.Wrap(err, "respond to seccomp notification")
+		}
+	}
+}
+
+func (s *Supervisor) dispatch(req *libseccomp.ScmpNotifReq) (*Response, error) {
+	name, err := req.Data.Syscall.GetName()
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve notified syscall name")
+	}
+
+	handler, ok := s.handlers[name]
+	if !ok {
+		return &Response{Flags: RespFlagContinue}, nil
+	}
+
+	resp, err := handler(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "handle syscall %s", name)
+	}
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+//go:build seccomp && cgo
+// +build seccomp,cgo
+
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	libseccomp "github.com/seccomp/libseccomp-golang"
+)
+
+func notifReqFor(t *testing.T, syscallName string) *libseccomp.ScmpNotifReq {
+	t.Helper()
+	nr, err := libseccomp.GetSyscallFromName(syscallName)
+	if err != nil {
+		t.Fatalf("GetSyscallFromName(%q) returned error: %v", syscallName, err)
+	}
+	return &libseccomp.ScmpNotifReq{Data: libseccomp.ScmpNotifData{Syscall: nr}}
+}
+
+func TestSupervisorDispatchUsesRegisteredHandler(t *testing.T) {
+	s := &Supervisor{handlers: map[string]Handler{}}
+
+	var called bool
+	s.Handle("read", func(req *libseccomp.ScmpNotifReq) (*Response, error) {
+		called = true
+		return &Response{Val: 42}, nil
+	})
+
+	resp, err := s.dispatch(notifReqFor(t, "read"))
+	if err != nil {
+		t.Fatalf("dispatch() returned error: %v", err)
+	}
+	if !called {
+		t.Fatalf("expected the registered handler to be invoked")
+	}
+	if resp.Val != 42 {
+		t.Fatalf("expected the handler's Response to be returned, got %+v", resp)
+	}
+}
+
+func TestSupervisorDispatchDefaultsToContinue(t *testing.T) {
+	s := &Supervisor{handlers: map[string]Handler{}}
+
+	resp, err := s.dispatch(notifReqFor(t, "write"))
+	if err != nil {
+		t.Fatalf("dispatch() returned error: %v", err)
+	}
+	if resp.Flags != RespFlagContinue {
+		t.Fatalf("expected a syscall with no registered Handler to continue, got %+v", resp)
+	}
+}
+
+func TestSupervisorDispatchPropagatesHandlerError(t *testing.T) {
+	s := &Supervisor{handlers: map[string]Handler{}}
+
+	wantErr := errors.New("handler failed")
+	s.Handle("mount", func(req *libseccomp.ScmpNotifReq) (*Response, error) {
+		return nil, wantErr
+	})
+
+	if _, err := s.dispatch(notifReqFor(t, "mount")); err == nil {
+		t.Fatalf("expected dispatch() to propagate the handler's error")
+	}
+}
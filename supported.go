@@ -0,0 +1,194 @@
+//go:build seccomp && cgo
+// +build seccomp,cgo
+
+package seccomp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	libseccomp "github.com/seccomp/libseccomp-golang"
+)
+
+// Supported reports which actions, comparison operators and architectures
+// the running kernel and linked libseccomp actually support, so a profile
+// authored against a newer kernel can be adjusted before it hard-fails on
+// an older host.
+type Supported struct {
+	Actions       map[Action]bool
+	Operators     map[Operator]bool
+	Architectures map[Arch]bool
+}
+
+// minAPI below which a given action isn't available, per libseccomp's own
+// SCMP_ACT_* versioning.
+var actionMinAPI = map[Action]uint{
+	ActKillProcess: 3,
+	ActLog:         3,
+	ActNotify:      6,
+}
+
+// GetSupported probes the running kernel and linked libseccomp for their
+// capabilities.
+func GetSupported() (*Supported, error) {
+	api, err := libseccomp.GetAPI()
+	if err != nil {
+		return nil, errors.Wrap(err, "get libseccomp API level")
+	}
+
+	supported := &Supported{
+		Actions:       map[Action]bool{},
+		Operators:     map[Operator]bool{},
+		Architectures: map[Arch]bool{},
+	}
+
+	for _, act := range []Action{
+		ActKill, ActKillProcess, ActTrap, ActErrno, ActTrace, ActAllow, ActLog, ActNotify,
+	} {
+		min, ok := actionMinAPI[act]
+		supported.Actions[act] = !ok || api >= min
+	}
+
+	// libseccomp has supported every SCMP_CMP_* comparator since its
+	// earliest API levels; nothing further to probe.
+	for _, op := range []Operator{
+		OpNotEqual, OpLessThan, OpLessEqual, OpEqualTo, OpGreaterEqual, OpGreaterThan, OpMaskedEqual,
+	} {
+		supported.Operators[op] = true
+	}
+
+	native, err := libseccomp.GetNativeArch()
+	if err != nil {
+		return nil, errors.Wrap(err, "get native architecture")
+	}
+
+	for _, arch := range []Arch{
+		ArchX86, ArchX86_64, ArchX32, ArchARM, ArchAARCH64, ArchMIPS, ArchMIPS64,
+		ArchMIPS64N32, ArchMIPSEL, ArchMIPSEL64, ArchMIPSEL64N32, ArchPPC, ArchPPC64,
+		ArchPPC64LE, ArchS390, ArchS390X,
+	} {
+		scmpArch, err := libseccomp.GetArchFromString(libseccompArchName(arch))
+		if err != nil {
+			supported.Architectures[arch] = false
+			continue
+		}
+		supported.Architectures[arch] = archAddable(scmpArch, native)
+	}
+
+	return supported, nil
+}
+
+// libseccompArchName converts one of this package's "SCMP_ARCH_*" Arch
+// values into the plain, lowercased name libseccomp-golang's
+// GetArchFromString expects (e.g. "SCMP_ARCH_X86_64" -> "x86_64"); passing
+// the SCMP_ARCH_ value through unchanged never matches and GetArchFromString
+// always errors.
+func libseccompArchName(arch Arch) string {
+	return strings.ToLower(strings.TrimPrefix(string(arch), "SCMP_ARCH_"))
+}
+
+// archAddable reports whether the running kernel and linked libseccomp can
+// actually add scmpArch to a filter. GetArchFromString is a pure
+// string-to-enum lookup that succeeds for every architecture libseccomp's
+// headers know about, regardless of what the host can enforce, so the only
+// way to tell is to exercise AddArch against a throwaway filter. Every
+// ScmpFilter is seeded with its native architecture already, so that one
+// is skipped as an obvious, cheap true rather than spending a filter on it
+// (AddArch itself would also just succeed, since it treats "already
+// present" as a silent no-op).
+func archAddable(scmpArch, native libseccomp.ScmpArch) bool {
+	if scmpArch == native {
+		return true
+	}
+
+	filter, err := libseccomp.NewFilter(libseccomp.ActAllow)
+	if err != nil {
+		return false
+	}
+	defer filter.Release()
+
+	return filter.AddArch(scmpArch) == nil
+}
+
+// Warning describes one adjustment Downgrade made to a profile.
+type Warning struct {
+	Syscall string
+	Message string
+}
+
+// downgradeAction maps an unsupported action to the closest safe
+// fallback.
+var downgradeAction = map[Action]Action{
+	ActKillProcess: ActKill,
+	ActLog:         ActAllow,
+	ActNotify:      ActErrno,
+}
+
+// Downgrade rewrites profile for a host with the capabilities in caps,
+// returning the adjusted profile together with a Warning for each rewrite
+// it made. Architectures caps doesn't report as supported are dropped, and
+// an unsupported KILL_PROCESS, LOG or NOTIFY action (default or
+// per-syscall) is replaced by its downgradeAction fallback. Other
+// unsupported actions and all operators are left as they are, and a
+// fallback is not itself checked against caps. The input profile is not
+// modified.
+func Downgrade(profile *Seccomp, caps Supported) (*Seccomp, []Warning) {
+	if profile == nil {
+		return nil, nil
+	}
+
+	var warnings []Warning
+
+	out := &Seccomp{
+		DefaultAction:   profile.DefaultAction,
+		DefaultErrnoRet: copyUintPtr(profile.DefaultErrnoRet),
+	}
+
+	if fallback, ok := downgradeAction[out.DefaultAction]; ok && !caps.Actions[out.DefaultAction] {
+		warnings = append(warnings, Warning{
+			Message: fmt.Sprintf("default action %s unsupported, downgrading to %s", out.DefaultAction, fallback),
+		})
+		out.DefaultAction = fallback
+	}
+
+	for _, arch := range profile.Architectures {
+		if caps.Architectures[arch] {
+			out.Architectures = append(out.Architectures, arch)
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Message: fmt.Sprintf("architecture %s unsupported, dropping", arch),
+		})
+	}
+
+	for _, call := range profile.Syscalls {
+		if call == nil {
+			continue
+		}
+		downgraded := *call
+		if fallback, ok := downgradeAction[downgraded.Action]; ok && !caps.Actions[downgraded.Action] {
+			warnings = append(warnings, Warning{
+				Syscall: call.Name,
+				Message: fmt.Sprintf("action %s unsupported, downgrading to %s", downgraded.Action, fallback),
+			})
+			downgraded.Action = fallback
+		}
+		out.Syscalls = append(out.Syscalls, &downgraded)
+	}
+
+	return out, warnings
+}
+
+// BuilderOptions configures BuildFilterWithOptions.
+type BuilderOptions struct {
+	// AutoDowngrade rewrites the profile via Downgrade, using a freshly
+	// probed Supported, before building the filter. This lets a profile
+	// authored against a newer kernel build cleanly on an older one
+	// instead of hard-failing.
+	AutoDowngrade bool
+
+	// Hook, if set, is notified of every skipped syscall, added rule,
+	// added architecture and args-fallback while the filter is built.
+	Hook Hook
+}
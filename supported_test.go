@@ -0,0 +1,99 @@
+//go:build seccomp && cgo
+// +build seccomp,cgo
+
+package seccomp
+
+import (
+	"testing"
+
+	libseccomp "github.com/seccomp/libseccomp-golang"
+)
+
+func TestArchAddableAcceptsNativeArch(t *testing.T) {
+	native, err := libseccomp.GetNativeArch()
+	if err != nil {
+		t.Fatalf("GetNativeArch() returned error: %v", err)
+	}
+
+	if !archAddable(native, native) {
+		t.Fatalf("archAddable(native, native) = false, want true")
+	}
+}
+
+func TestGetSupportedProbesNativeArchitecture(t *testing.T) {
+	native, err := libseccomp.GetNativeArch()
+	if err != nil {
+		t.Fatalf("GetNativeArch() returned error: %v", err)
+	}
+
+	var nativeName Arch
+	for _, arch := range []Arch{
+		ArchX86, ArchX86_64, ArchX32, ArchARM, ArchAARCH64, ArchMIPS, ArchMIPS64,
+		ArchMIPS64N32, ArchMIPSEL, ArchMIPSEL64, ArchMIPSEL64N32, ArchPPC, ArchPPC64,
+		ArchPPC64LE, ArchS390, ArchS390X,
+	} {
+		scmpArch, err := libseccomp.GetArchFromString(libseccompArchName(arch))
+		if err == nil && scmpArch == native {
+			nativeName = arch
+			break
+		}
+	}
+	if nativeName == "" {
+		t.Skip("native architecture not among the probed list")
+	}
+
+	supported, err := GetSupported()
+	if err != nil {
+		t.Fatalf("GetSupported() returned error: %v", err)
+	}
+	if !supported.Architectures[nativeName] {
+		t.Fatalf("expected native architecture %s to be reported supported", nativeName)
+	}
+}
+
+func TestLibseccompArchName(t *testing.T) {
+	cases := map[Arch]string{
+		ArchX86_64:  "x86_64",
+		ArchAARCH64: "aarch64",
+		ArchPPC64LE: "ppc64le",
+	}
+	for arch, want := range cases {
+		if got := libseccompArchName(arch); got != want {
+			t.Fatalf("libseccompArchName(%s) = %q, want %q", arch, got, want)
+		}
+	}
+}
+
+func TestDowngradeRewritesUnsupportedActions(t *testing.T) {
+	caps := Supported{
+		Actions: map[Action]bool{
+			ActAllow: true,
+			ActErrno: true,
+			ActKill:  true,
+		},
+		Architectures: map[Arch]bool{ArchX86_64: true},
+	}
+
+	profile := &Seccomp{
+		DefaultAction: ActKillProcess,
+		Architectures: []Arch{ArchX86_64, ArchPPC64LE},
+		Syscalls: []*Syscall{
+			{Name: "dmesg_restrict", Action: ActLog},
+		},
+	}
+
+	out, warnings := Downgrade(profile, caps)
+
+	if out.DefaultAction != ActKill {
+		t.Fatalf("expected default action to downgrade to ActKill, got %s", out.DefaultAction)
+	}
+	if len(out.Architectures) != 1 || out.Architectures[0] != ArchX86_64 {
+		t.Fatalf("expected unsupported architecture to be dropped, got %v", out.Architectures)
+	}
+	if out.Syscalls[0].Action != ActAllow {
+		t.Fatalf("expected ActLog to downgrade to ActAllow, got %s", out.Syscalls[0].Action)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %d: %+v", len(warnings), warnings)
+	}
+}
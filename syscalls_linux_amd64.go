@@ -0,0 +1,122 @@
+//go:build seccomp && (!cgo || nativebpf)
+// +build seccomp
+// +build !cgo nativebpf
+
+package seccomp
+
+import "golang.org/x/sys/unix"
+
+// syscallNumbers maps syscall names to their number on the build
+// architecture, via the per-arch constants golang.org/x/sys/unix already
+// generates from the kernel headers. It covers the syscalls exercised by
+// the common container default profiles; GetSyscallFromName-style full
+// coverage can be added here as profiles need it.
+var syscallNumbers = map[string]int32{
+	"read":            unix.SYS_READ,
+	"write":           unix.SYS_WRITE,
+	"open":            unix.SYS_OPEN,
+	"close":           unix.SYS_CLOSE,
+	"stat":            unix.SYS_STAT,
+	"fstat":           unix.SYS_FSTAT,
+	"lstat":           unix.SYS_LSTAT,
+	"poll":            unix.SYS_POLL,
+	"lseek":           unix.SYS_LSEEK,
+	"mmap":            unix.SYS_MMAP,
+	"mprotect":        unix.SYS_MPROTECT,
+	"munmap":          unix.SYS_MUNMAP,
+	"brk":             unix.SYS_BRK,
+	"rt_sigaction":    unix.SYS_RT_SIGACTION,
+	"rt_sigprocmask":  unix.SYS_RT_SIGPROCMASK,
+	"rt_sigreturn":    unix.SYS_RT_SIGRETURN,
+	"ioctl":           unix.SYS_IOCTL,
+	"pread64":         unix.SYS_PREAD64,
+	"pwrite64":        unix.SYS_PWRITE64,
+	"readv":           unix.SYS_READV,
+	"writev":          unix.SYS_WRITEV,
+	"access":          unix.SYS_ACCESS,
+	"pipe":            unix.SYS_PIPE,
+	"select":          unix.SYS_SELECT,
+	"sched_yield":     unix.SYS_SCHED_YIELD,
+	"mremap":          unix.SYS_MREMAP,
+	"msync":           unix.SYS_MSYNC,
+	"mincore":         unix.SYS_MINCORE,
+	"madvise":         unix.SYS_MADVISE,
+	"dup":             unix.SYS_DUP,
+	"dup2":            unix.SYS_DUP2,
+	"pause":           unix.SYS_PAUSE,
+	"nanosleep":       unix.SYS_NANOSLEEP,
+	"getpid":          unix.SYS_GETPID,
+	"socket":          unix.SYS_SOCKET,
+	"connect":         unix.SYS_CONNECT,
+	"accept":          unix.SYS_ACCEPT,
+	"sendto":          unix.SYS_SENDTO,
+	"recvfrom":        unix.SYS_RECVFROM,
+	"bind":            unix.SYS_BIND,
+	"listen":          unix.SYS_LISTEN,
+	"clone":           unix.SYS_CLONE,
+	"fork":            unix.SYS_FORK,
+	"vfork":           unix.SYS_VFORK,
+	"execve":          unix.SYS_EXECVE,
+	"exit":            unix.SYS_EXIT,
+	"wait4":           unix.SYS_WAIT4,
+	"kill":            unix.SYS_KILL,
+	"uname":           unix.SYS_UNAME,
+	"fcntl":           unix.SYS_FCNTL,
+	"flock":           unix.SYS_FLOCK,
+	"fsync":           unix.SYS_FSYNC,
+	"ftruncate":       unix.SYS_FTRUNCATE,
+	"getdents":        unix.SYS_GETDENTS,
+	"getcwd":          unix.SYS_GETCWD,
+	"chdir":           unix.SYS_CHDIR,
+	"rename":          unix.SYS_RENAME,
+	"mkdir":           unix.SYS_MKDIR,
+	"rmdir":           unix.SYS_RMDIR,
+	"unlink":          unix.SYS_UNLINK,
+	"readlink":        unix.SYS_READLINK,
+	"chmod":           unix.SYS_CHMOD,
+	"chown":           unix.SYS_CHOWN,
+	"getuid":          unix.SYS_GETUID,
+	"getgid":          unix.SYS_GETGID,
+	"setuid":          unix.SYS_SETUID,
+	"setgid":          unix.SYS_SETGID,
+	"getppid":         unix.SYS_GETPPID,
+	"prctl":           unix.SYS_PRCTL,
+	"arch_prctl":      unix.SYS_ARCH_PRCTL,
+	"openat":          unix.SYS_OPENAT,
+	"mkdirat":         unix.SYS_MKDIRAT,
+	"unlinkat":        unix.SYS_UNLINKAT,
+	"fchownat":        unix.SYS_FCHOWNAT,
+	"newfstatat":      unix.SYS_NEWFSTATAT,
+	"set_tid_address": unix.SYS_SET_TID_ADDRESS,
+	"set_robust_list": unix.SYS_SET_ROBUST_LIST,
+	"futex":           unix.SYS_FUTEX,
+	"epoll_create":    unix.SYS_EPOLL_CREATE,
+	"epoll_ctl":       unix.SYS_EPOLL_CTL,
+	"epoll_wait":      unix.SYS_EPOLL_WAIT,
+	"eventfd2":        unix.SYS_EVENTFD2,
+	"signalfd4":       unix.SYS_SIGNALFD4,
+	"timerfd_create":  unix.SYS_TIMERFD_CREATE,
+	"clock_gettime":   unix.SYS_CLOCK_GETTIME,
+	"clock_nanosleep": unix.SYS_CLOCK_NANOSLEEP,
+	"exit_group":      unix.SYS_EXIT_GROUP,
+	"tgkill":          unix.SYS_TGKILL,
+	"mount":           unix.SYS_MOUNT,
+	"umount2":         unix.SYS_UMOUNT2,
+	"pivot_root":      unix.SYS_PIVOT_ROOT,
+	"chroot":          unix.SYS_CHROOT,
+	"capget":          unix.SYS_CAPGET,
+	"capset":          unix.SYS_CAPSET,
+	"setns":           unix.SYS_SETNS,
+	"unshare":         unix.SYS_UNSHARE,
+	"ptrace":          unix.SYS_PTRACE,
+	"seccomp":         unix.SYS_SECCOMP,
+	"getrandom":       unix.SYS_GETRANDOM,
+	"statx":           unix.SYS_STATX,
+}
+
+// syscallNumber looks up the syscall number for name on the build
+// architecture.
+func syscallNumber(name string) (int32, bool) {
+	nr, ok := syscallNumbers[name]
+	return nr, ok
+}
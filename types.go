@@ -0,0 +1,83 @@
+package seccomp
+
+// Seccomp represents the config for a seccomp profile for syscall
+// restriction, following the OCI seccomp spec loosely enough to be
+// convertible to and from it.
+type Seccomp struct {
+	DefaultAction   Action     `json:"defaultAction"`
+	DefaultErrnoRet *uint      `json:"defaultErrnoRet,omitempty"`
+	Architectures   []Arch     `json:"architectures,omitempty"`
+	Syscalls        []*Syscall `json:"syscalls"`
+}
+
+// Arch used for additional seccomp architectures.
+type Arch string
+
+// Architecture constants for the subset of architectures libseccomp knows
+// about and that this package is able to target.
+const (
+	ArchX86         Arch = "SCMP_ARCH_X86"
+	ArchX86_64      Arch = "SCMP_ARCH_X86_64"
+	ArchX32         Arch = "SCMP_ARCH_X32"
+	ArchARM         Arch = "SCMP_ARCH_ARM"
+	ArchAARCH64     Arch = "SCMP_ARCH_AARCH64"
+	ArchMIPS        Arch = "SCMP_ARCH_MIPS"
+	ArchMIPS64      Arch = "SCMP_ARCH_MIPS64"
+	ArchMIPS64N32   Arch = "SCMP_ARCH_MIPS64N32"
+	ArchMIPSEL      Arch = "SCMP_ARCH_MIPSEL"
+	ArchMIPSEL64    Arch = "SCMP_ARCH_MIPSEL64"
+	ArchMIPSEL64N32 Arch = "SCMP_ARCH_MIPSEL64N32"
+	ArchPPC         Arch = "SCMP_ARCH_PPC"
+	ArchPPC64       Arch = "SCMP_ARCH_PPC64"
+	ArchPPC64LE     Arch = "SCMP_ARCH_PPC64LE"
+	ArchS390        Arch = "SCMP_ARCH_S390"
+	ArchS390X       Arch = "SCMP_ARCH_S390X"
+)
+
+// Syscall is used to match a group of syscalls in a seccomp profile.
+type Syscall struct {
+	Name     string `json:"name,omitempty"`
+	Action   Action `json:"action"`
+	Args     []*Arg `json:"args"`
+	Comment  string `json:"comment"`
+	ErrnoRet *uint  `json:"errnoRet,omitempty"`
+}
+
+// Arg used for matching specific syscall arguments in a seccomp profile.
+type Arg struct {
+	Index    uint     `json:"index"`
+	Value    uint64   `json:"value"`
+	ValueTwo uint64   `json:"valueTwo"`
+	Op       Operator `json:"op"`
+}
+
+// Operator used to match syscall arguments in Arg.
+type Operator string
+
+// Operators available to match syscall arguments, mirroring the
+// libseccomp SCMP_CMP_* comparators.
+const (
+	OpNotEqual     Operator = "SCMP_CMP_NE"
+	OpLessThan     Operator = "SCMP_CMP_LT"
+	OpLessEqual    Operator = "SCMP_CMP_LE"
+	OpEqualTo      Operator = "SCMP_CMP_EQ"
+	OpGreaterEqual Operator = "SCMP_CMP_GE"
+	OpGreaterThan  Operator = "SCMP_CMP_GT"
+	OpMaskedEqual  Operator = "SCMP_CMP_MASKED_EQ"
+)
+
+// Action taken upon rule match in a seccomp profile.
+type Action string
+
+// Actions available to be taken upon rule match, mirroring the
+// libseccomp SCMP_ACT_* actions.
+const (
+	ActKill        Action = "SCMP_ACT_KILL"
+	ActKillProcess Action = "SCMP_ACT_KILL_PROCESS"
+	ActTrap        Action = "SCMP_ACT_TRAP"
+	ActErrno       Action = "SCMP_ACT_ERRNO"
+	ActTrace       Action = "SCMP_ACT_TRACE"
+	ActAllow       Action = "SCMP_ACT_ALLOW"
+	ActLog         Action = "SCMP_ACT_LOG"
+	ActNotify      Action = "SCMP_ACT_NOTIFY"
+)
@@ -1,4 +1,5 @@
-// +build seccomp
+//go:build seccomp && cgo && !nativebpf
+// +build seccomp,cgo,!nativebpf
 
 package seccomp
 